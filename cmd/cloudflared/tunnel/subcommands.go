@@ -2,6 +2,7 @@ package tunnel
 
 import (
 	"crypto/rand"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -9,7 +10,9 @@ import (
 	"path/filepath"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"text/tabwriter"
 	"time"
 
@@ -18,6 +21,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/urfave/cli/v2"
 	"github.com/urfave/cli/v2/altsrc"
+	"github.com/zalando/go-keyring"
 	"golang.org/x/net/idna"
 	yaml "gopkg.in/yaml.v2"
 
@@ -35,6 +39,21 @@ const (
 	CredFileFlag         = "credentials-file"
 	overwriteDNSFlagName = "overwrite-dns"
 
+	CredentialsStoreFlag     = "credentials-store"
+	credentialsStoreFile     = "file"
+	credentialsStoreKeyring  = "keyring"
+	credentialsStoreKeychain = "keychain"
+	credentialsStoreDPAPI    = "dpapi"
+
+	// credentialsKeyringService is the service name tunnel credentials are namespaced
+	// under when stored via an OS secret store.
+	credentialsKeyringService = "cloudflared-tunnel-credentials"
+
+	hostnameIDNAModeFlagName = "hostname-idna-mode"
+	idnaModeStrict           = "strict"
+	idnaModeCompatible       = "compatible"
+	idnaModeRegistration     = "registration"
+
 	LogFieldTunnelID = "tunnelID"
 )
 
@@ -69,7 +88,11 @@ var (
 	outputFormatFlag = &cli.StringFlag{
 		Name:    "output",
 		Aliases: []string{"o"},
-		Usage:   "Render output using given `FORMAT`. Valid options are 'json' or 'yaml'",
+		Usage:   "Render output using given `FORMAT`. Valid options are 'json', 'yaml', 'csv' or 'table'",
+	}
+	columnsFlag = &cli.StringFlag{
+		Name:  "columns",
+		Usage: "Comma-separated list of `COLUMNS` to print when using 'csv' or 'table' output. Defaults to all columns.",
 	}
 	sortByFlag = &cli.StringFlag{
 		Name:    "sort-by",
@@ -101,8 +124,15 @@ var (
 		Usage:   "Filepath at which to read/write the tunnel credentials",
 		EnvVars: []string{"TUNNEL_CRED_FILE"},
 	}
-	credentialsFileFlag = altsrc.NewStringFlag(credentialsFileFlagCLIOnly)
-	forceDeleteFlag     = &cli.BoolFlag{
+	credentialsFileFlag  = altsrc.NewStringFlag(credentialsFileFlagCLIOnly)
+	credentialsStoreFlag = altsrc.NewStringFlag(&cli.StringFlag{
+		Name:  CredentialsStoreFlag,
+		Value: credentialsStoreFile,
+		Usage: fmt.Sprintf("Backend used to store/retrieve tunnel credentials. Valid options are '%s', '%s', '%s' or '%s'; the last three all use the current platform's OS secret store (Secret Service on Linux, Keychain on macOS, Credential Manager on Windows).",
+			credentialsStoreFile, credentialsStoreKeyring, credentialsStoreKeychain, credentialsStoreDPAPI),
+		EnvVars: []string{"TUNNEL_CREDENTIALS_STORE"},
+	})
+	forceDeleteFlag = &cli.BoolFlag{
 		Name:    "force",
 		Aliases: []string{"f"},
 		Usage: "Cleans up any stale connections before the tunnel is deleted. cloudflared will not " +
@@ -140,8 +170,35 @@ var (
 		Usage:   `Overwrites existing DNS records with this hostname`,
 		EnvVars: []string{"TUNNEL_FORCE_PROVISIONING_DNS"},
 	}
+	filterNameRegexFlag = &cli.StringFlag{
+		Name:  "name-regex",
+		Usage: "Select non-deleted tunnels whose name matches the given regular expression, instead of passing tunnel IDs/names as arguments",
+	}
+	filterOlderThanFlag = &cli.TimestampFlag{
+		Name:   "older-than",
+		Usage:  "Select non-deleted tunnels created before the given `TIME`, instead of passing tunnel IDs/names as arguments",
+		Layout: tunnelstore.TimeLayout,
+	}
+	filterNoConnectionsFlag = &cli.BoolFlag{
+		Name:  "no-connections",
+		Usage: "Select non-deleted tunnels that have no active connections, instead of passing tunnel IDs/names as arguments",
+	}
+	hostnameIDNAModeFlag = &cli.StringFlag{
+		Name:  hostnameIDNAModeFlagName,
+		Value: idnaModeStrict,
+		Usage: fmt.Sprintf("Controls how internationalized hostnames are validated. Valid options are '%s' (current, most restrictive), '%s' (allows the Unicode TR46 transitional mapping used by older browsers) or '%s' (non-transitional mapping, matching current ICANN registration rules).",
+			idnaModeStrict, idnaModeCompatible, idnaModeRegistration),
+	}
 )
 
+// batchOpConcurrency bounds how many tunnels a filter-selected batch operation
+// (delete, cleanup) will act on at once.
+const batchOpConcurrency = 4
+
+func selectorFlags() []cli.Flag {
+	return []cli.Flag{filterNameRegexFlag, filterOlderThanFlag, filterNoConnectionsFlag}
+}
+
 func buildCreateCommand() *cli.Command {
 	return &cli.Command{
 		Name:      "create",
@@ -154,7 +211,7 @@ func buildCreateCommand() *cli.Command {
   For example, to create a tunnel named 'my-tunnel' run:
 
   $ cloudflared tunnel create my-tunnel`,
-		Flags:              []cli.Flag{outputFormatFlag, credentialsFileFlagCLIOnly},
+		Flags:              []cli.Flag{outputFormatFlag, credentialsFileFlagCLIOnly, credentialsStoreFlag},
 		CustomHelpTemplate: commandHelpTemplate(),
 	}
 }
@@ -180,7 +237,7 @@ func createCommand(c *cli.Context) error {
 	warningChecker := updater.StartWarningCheck(c)
 	defer warningChecker.LogWarningIfAny(sc.log)
 
-	_, err = sc.create(name, c.String(CredFileFlag))
+	_, err = sc.create(name, c.String(CredFileFlag), c.String(CredentialsStoreFlag))
 	return errors.Wrap(err, "failed to create tunnel")
 }
 
@@ -190,20 +247,220 @@ func tunnelFilePath(tunnelID uuid.UUID, directory string) (string, error) {
 	return homedir.Expand(filePath)
 }
 
-// writeTunnelCredentials saves `credentials` as a JSON into `filePath`, only if
-// the file does not exist already
-func writeTunnelCredentials(filePath string, credentials *connection.Credentials) error {
+// writeTunnelCredentials saves `credentials` into the configured credentials store, only
+// if it isn't already present there. By default this is a JSON file at `filePath`, but if
+// credentialsStore names an OS secret store, the JSON blob is saved there instead, keyed
+// by the tunnel ID.
+func writeTunnelCredentials(filePath string, credentialsStore string, credentials *connection.Credentials) error {
+	body, err := json.Marshal(credentials)
+	if err != nil {
+		return errors.Wrap(err, "Unable to marshal tunnel credentials to JSON")
+	}
+
+	if isOSKeyringStore(credentialsStore) {
+		if _, err := keyring.Get(credentialsKeyringService, credentials.TunnelID.String()); err != keyring.ErrNotFound {
+			if err == nil {
+				return fmt.Errorf("credentials for tunnel %s already exist in %s", credentials.TunnelID, credentialsStore)
+			}
+			return err
+		}
+		return keyring.Set(credentialsKeyringService, credentials.TunnelID.String(), string(body))
+	}
+
 	if _, err := os.Stat(filePath); !os.IsNotExist(err) {
 		if err == nil {
 			return fmt.Errorf("%s already exists", filePath)
 		}
 		return err
 	}
-	body, err := json.Marshal(credentials)
+	return ioutil.WriteFile(filePath, body, 0400)
+}
+
+// readTunnelCredentials loads the credentials for tunnelID from whichever store was
+// configured, transparently falling back to the on-disk JSON file for the default "file"
+// store.
+func readTunnelCredentials(tunnelID uuid.UUID, filePath string, credentialsStore string) (*connection.Credentials, error) {
+	var body []byte
+	var err error
+	if isOSKeyringStore(credentialsStore) {
+		var secret string
+		secret, err = keyring.Get(credentialsKeyringService, tunnelID.String())
+		body = []byte(secret)
+	} else {
+		body, err = ioutil.ReadFile(filePath)
+	}
 	if err != nil {
-		return errors.Wrap(err, "Unable to marshal tunnel credentials to JSON")
+		return nil, errors.Wrap(err, "unable to read tunnel credentials")
 	}
-	return ioutil.WriteFile(filePath, body, 400)
+
+	var credentials connection.Credentials
+	if err := json.Unmarshal(body, &credentials); err != nil {
+		return nil, errors.Wrap(err, "unable to parse tunnel credentials")
+	}
+	return &credentials, nil
+}
+
+// migrateCredentialsToStore moves the credentials for tunnelID out of the on-disk JSON
+// file at filePath and into credentialsStore, if credentialsStore names an OS secret
+// store and the file still exists.
+func migrateCredentialsToStore(tunnelID uuid.UUID, filePath string, credentialsStore string) error {
+	if !isOSKeyringStore(credentialsStore) {
+		return nil
+	}
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		return nil
+	}
+
+	credentials, err := readTunnelCredentials(tunnelID, filePath, credentialsStoreFile)
+	if err != nil {
+		return errors.Wrap(err, "unable to read existing credentials file for migration")
+	}
+	body, err := marshalCredentials(credentials)
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal tunnel credentials")
+	}
+	if _, err := keyring.Get(credentialsKeyringService, tunnelID.String()); err != keyring.ErrNotFound {
+		if err == nil {
+			return fmt.Errorf("credentials for tunnel %s already exist in %s", tunnelID, credentialsStore)
+		}
+		return err
+	}
+	if err := keyring.Set(credentialsKeyringService, tunnelID.String(), string(body)); err != nil {
+		return errors.Wrapf(err, "unable to save credentials into %s", credentialsStore)
+	}
+	return os.Remove(filePath)
+}
+
+func marshalCredentials(credentials *connection.Credentials) ([]byte, error) {
+	return json.Marshal(credentials)
+}
+
+func isOSKeyringStore(credentialsStore string) bool {
+	switch credentialsStore {
+	case credentialsStoreKeyring, credentialsStoreKeychain, credentialsStoreDPAPI:
+		return true
+	default:
+		return false
+	}
+}
+
+// buildRenameCommand builds the "rename" subcommand. Like the other build*Command
+// functions in this file, it must be added to the "tunnel" command's Subcommands list
+// where that's assembled.
+func buildRenameCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "rename",
+		Action:    cliutil.ConfiguredAction(renameCommand),
+		Usage:     "Rename a tunnel",
+		UsageText: "cloudflared tunnel [tunnel command options] rename [subcommand options] TUNNEL NEW-NAME",
+		Description: `cloudflared tunnel rename changes the name of an existing tunnel, identified by its UUID or current name.
+  Unlike deleting and recreating the tunnel, renaming keeps the existing credentials and any DNS/LB routes intact.
+
+  For example, to rename a tunnel currently called 'my-tunnel' to 'my-renamed-tunnel' run:
+
+  $ cloudflared tunnel rename my-tunnel my-renamed-tunnel`,
+		Flags:              []cli.Flag{outputFormatFlag, credentialsFileFlagCLIOnly},
+		CustomHelpTemplate: commandHelpTemplate(),
+	}
+}
+
+func renameCommand(c *cli.Context) error {
+	sc, err := newSubcommandContext(c)
+	if err != nil {
+		return err
+	}
+
+	if c.NArg() != 2 {
+		return cliutil.UsageError(`"cloudflared tunnel rename" requires exactly 2 arguments, the ID or name of the tunnel to rename, and its new name.`)
+	}
+	newName := c.Args().Get(1)
+	if !validateName(newName, false) {
+		return errors.Errorf("%s is not a valid tunnel name", newName)
+	}
+
+	warningChecker := updater.StartWarningCheck(c)
+	defer warningChecker.LogWarningIfAny(sc.log)
+
+	tunnelID, err := sc.findID(c.Args().First())
+	if err != nil {
+		return errors.Wrap(err, "error parsing tunnel ID")
+	}
+
+	oldTunnel, err := getTunnel(sc, tunnelID)
+	if err != nil {
+		return err
+	}
+
+	if err := sc.rename(tunnelID, newName); err != nil {
+		return errors.Wrap(err, "failed to rename tunnel")
+	}
+
+	if credFilePath := c.String(CredFileFlag); credFilePath != "" {
+		if err := renameTunnelCredentials(credFilePath, newName); err != nil {
+			// The rename already succeeded against the edge, but we couldn't update the local
+			// credentials file to match, so roll the edge name back to avoid leaving the two
+			// out of sync.
+			if rollbackErr := sc.rename(tunnelID, oldTunnel.Name); rollbackErr != nil {
+				sc.log.Error().Err(rollbackErr).Msg("failed to roll back tunnel rename after updating local credentials failed")
+				return errors.Wrapf(err, "failed to update local credentials file, and rolling the name back to %s also failed", oldTunnel.Name)
+			}
+			return errors.Wrap(err, "failed to update local credentials file, rename was rolled back")
+		}
+	}
+
+	if outputFormat := c.String(outputFormatFlag.Name); outputFormat != "" {
+		renamedTunnel, err := getTunnel(sc, tunnelID)
+		if err != nil {
+			return err
+		}
+		return renderOutput(outputFormat, renamedTunnel)
+	}
+
+	fmt.Printf("Tunnel %s has been renamed to %s\n", tunnelID, newName)
+	return nil
+}
+
+// rename renames the tunnel identified by tunnelID to newName against the Cloudflare edge.
+func (sc *subcommandContext) rename(tunnelID uuid.UUID, newName string) error {
+	client, err := sc.client()
+	if err != nil {
+		return err
+	}
+	return client.RenameTunnel(tunnelID, newName)
+}
+
+// renameTunnelCredentials updates the tunnel name embedded in the credentials file at
+// filePath, if the file exists and the name is actually embedded in it.
+func renameTunnelCredentials(filePath string, newName string) error {
+	body, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	var credentials map[string]interface{}
+	if err := json.Unmarshal(body, &credentials); err != nil {
+		return errors.Wrap(err, "unable to parse credentials file as JSON")
+	}
+
+	if _, ok := credentials["TunnelName"]; !ok {
+		return nil
+	}
+	credentials["TunnelName"] = newName
+
+	updatedBody, err := json.Marshal(credentials)
+	if err != nil {
+		return errors.Wrap(err, "unable to marshal updated credentials")
+	}
+	// Credentials files are written read-only (0400), so make the file writable just long
+	// enough to rewrite it.
+	if err := os.Chmod(filePath, 0600); err != nil {
+		return err
+	}
+	defer os.Chmod(filePath, 0400)
+	return ioutil.WriteFile(filePath, updatedBody, 0400)
 }
 
 func buildListCommand() *cli.Command {
@@ -215,6 +472,7 @@ func buildListCommand() *cli.Command {
 		Description: "cloudflared tunnel list will display all active tunnels, their created time and associated connections. Use -d flag to include deleted tunnels. See the list of options to filter the list",
 		Flags: []cli.Flag{
 			outputFormatFlag,
+			columnsFlag,
 			showDeletedFlag,
 			listNameFlag,
 			listExistedAtFlag,
@@ -290,7 +548,18 @@ func listCommand(c *cli.Context) error {
 	}
 
 	if outputFormat := c.String(outputFormatFlag.Name); outputFormat != "" {
-		return renderOutput(outputFormat, tunnels)
+		switch outputFormat {
+		case "csv", "table":
+			showRecentlyDisconnected := c.Bool("show-recently-disconnected")
+			columns, err := filterColumns(tunnelColumns, parseColumnsFlag(c))
+			if err != nil {
+				return err
+			}
+			headers, rows := tunnelRows(tunnels, columns, showRecentlyDisconnected)
+			return renderRows(outputFormat, headers, rows)
+		default:
+			return renderOutput(outputFormat, tunnels)
+		}
 	}
 
 	if len(tunnels) > 0 {
@@ -358,6 +627,7 @@ func buildInfoCommand() *cli.Command {
 		Description: "cloudflared tunnel info displays details about the active connectors for a given tunnel (identified by name or uuid).",
 		Flags: []cli.Flag{
 			outputFormatFlag,
+			columnsFlag,
 			showRecentlyDisconnected,
 			sortInfoByFlag,
 			invertInfoSortFlag,
@@ -432,7 +702,18 @@ func tunnelInfo(c *cli.Context) error {
 	}
 
 	if outputFormat := c.String(outputFormatFlag.Name); outputFormat != "" {
-		return renderOutput(outputFormat, info)
+		switch outputFormat {
+		case "csv", "table":
+			showRecentlyDisconnected := c.Bool("show-recently-disconnected")
+			columns, err := filterColumns(connectorColumns, parseColumnsFlag(c))
+			if err != nil {
+				return err
+			}
+			headers, rows := connectorRows(info.Connectors, columns, showRecentlyDisconnected)
+			return renderRows(outputFormat, headers, rows)
+		default:
+			return renderOutput(outputFormat, info)
+		}
 	}
 
 	if len(clients) > 0 {
@@ -518,7 +799,7 @@ func buildDeleteCommand() *cli.Command {
 		Usage:              "Delete existing tunnel by UUID or name",
 		UsageText:          "cloudflared tunnel [tunnel command options] delete [subcommand options] TUNNEL",
 		Description:        "cloudflared tunnel delete will delete tunnels with the given tunnel UUIDs or names. A tunnel cannot be deleted if it has active connections. To delete the tunnel unconditionally, use -f flag.",
-		Flags:              []cli.Flag{credentialsFileFlagCLIOnly, forceDeleteFlag},
+		Flags:              append([]cli.Flag{credentialsFileFlagCLIOnly, forceDeleteFlag}, selectorFlags()...),
 		CustomHelpTemplate: commandHelpTemplate(),
 	}
 }
@@ -529,19 +810,126 @@ func deleteCommand(c *cli.Context) error {
 		return err
 	}
 
-	if c.NArg() < 1 {
-		return cliutil.UsageError(`"cloudflared tunnel delete" requires at least 1 argument, the ID or name of the tunnel to delete.`)
-	}
-
 	warningChecker := updater.StartWarningCheck(c)
 	defer warningChecker.LogWarningIfAny(sc.log)
 
-	tunnelIDs, err := sc.findIDs(c.Args().Slice())
+	tunnelIDs, fromFilter, err := tunnelIDsFromArgsOrFilter(sc, c)
 	if err != nil {
 		return err
 	}
+	if len(tunnelIDs) == 0 {
+		return cliutil.UsageError(`"cloudflared tunnel delete" requires at least 1 argument (the ID or name of the tunnel to delete) or a selector flag such as --name-regex, --older-than, or --no-connections.`)
+	}
 
-	return sc.delete(tunnelIDs)
+	if !fromFilter || len(tunnelIDs) == 1 {
+		return sc.delete(tunnelIDs)
+	}
+
+	results := runBatch(tunnelIDs, batchOpConcurrency, func(tunnelID uuid.UUID) error {
+		return sc.delete([]uuid.UUID{tunnelID})
+	})
+	return printBatchResults("delete", results)
+}
+
+// tunnelIDsFromArgsOrFilter returns the tunnel IDs explicitly named as positional
+// arguments, or, if none were given, the IDs of the non-deleted tunnels matching the
+// --name-regex/--older-than/--no-connections selector flags, plus whether the IDs came
+// from the selector. It is an error to supply both positional tunnel arguments and a
+// selector flag.
+func tunnelIDsFromArgsOrFilter(sc *subcommandContext, c *cli.Context) (ids []uuid.UUID, fromFilter bool, err error) {
+	hasSelector := c.String(filterNameRegexFlag.Name) != "" || c.Timestamp(filterOlderThanFlag.Name) != nil || c.Bool(filterNoConnectionsFlag.Name)
+	if c.NArg() > 0 {
+		if hasSelector {
+			return nil, false, cliutil.UsageError("cannot combine explicit tunnel IDs/names with --name-regex, --older-than, or --no-connections; pass one or the other")
+		}
+		ids, err = sc.findIDs(c.Args().Slice())
+		return ids, false, err
+	}
+	ids, err = filterTunnelIDs(sc, c)
+	return ids, true, err
+}
+
+func filterTunnelIDs(sc *subcommandContext, c *cli.Context) ([]uuid.UUID, error) {
+	nameRegexStr := c.String(filterNameRegexFlag.Name)
+	olderThan := c.Timestamp(filterOlderThanFlag.Name)
+	noConnections := c.Bool(filterNoConnectionsFlag.Name)
+	if nameRegexStr == "" && olderThan == nil && !noConnections {
+		return nil, nil
+	}
+
+	var nameRegex *regexp.Regexp
+	if nameRegexStr != "" {
+		var err error
+		nameRegex, err = regexp.Compile(nameRegexStr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s is not a valid regular expression", nameRegexStr)
+		}
+	}
+
+	filter := tunnelstore.NewFilter()
+	filter.NoDeleted()
+	tunnels, err := sc.list(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var tunnelIDs []uuid.UUID
+	for _, t := range tunnels {
+		if nameRegex != nil && !nameRegex.MatchString(t.Name) {
+			continue
+		}
+		if olderThan != nil && !t.CreatedAt.Before(*olderThan) {
+			continue
+		}
+		if noConnections && len(t.Connections) > 0 {
+			continue
+		}
+		tunnelIDs = append(tunnelIDs, t.ID)
+	}
+	return tunnelIDs, nil
+}
+
+// batchResult is the outcome of a single-tunnel op run as part of a batch.
+type batchResult struct {
+	tunnelID uuid.UUID
+	err      error
+}
+
+// runBatch runs op for every ID in tunnelIDs, with at most concurrency calls of op
+// running at once, and reports the outcome of each.
+func runBatch(tunnelIDs []uuid.UUID, concurrency int, op func(uuid.UUID) error) []batchResult {
+	results := make([]batchResult, len(tunnelIDs))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, tunnelID := range tunnelIDs {
+		wg.Add(1)
+		go func(i int, tunnelID uuid.UUID) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = batchResult{tunnelID: tunnelID, err: op(tunnelID)}
+		}(i, tunnelID)
+	}
+	wg.Wait()
+	return results
+}
+
+// printBatchResults prints a per-tunnel success/failure line for a batch op and
+// returns a summary error if any tunnel failed.
+func printBatchResults(action string, results []batchResult) error {
+	failed := 0
+	for _, result := range results {
+		if result.err != nil {
+			failed++
+			fmt.Printf("Failed to %s tunnel %s: %v\n", action, result.tunnelID, result.err)
+		} else {
+			fmt.Printf("Successfully ran %s on tunnel %s\n", action, result.tunnelID)
+		}
+	}
+	if failed > 0 {
+		return errors.Errorf("failed to %s %d out of %d tunnels", action, failed, len(results))
+	}
+	return nil
 }
 
 func renderOutput(format string, v interface{}) error {
@@ -557,10 +945,166 @@ func renderOutput(format string, v interface{}) error {
 	}
 }
 
+// column describes one field of tunnelstore.Tunnel or Info that can be selected for
+// 'csv'/'table' output via the --columns flag.
+type column struct {
+	Name   string
+	Header string
+}
+
+var tunnelColumns = []column{
+	{"id", "ID"},
+	{"name", "NAME"},
+	{"createdAt", "CREATED"},
+	{"connections", "CONNECTIONS"},
+}
+
+var connectorColumns = []column{
+	{"id", "CONNECTOR ID"},
+	{"createdAt", "CREATED"},
+	{"architecture", "ARCHITECTURE"},
+	{"version", "VERSION"},
+	{"originIP", "ORIGIN IP"},
+	{"edge", "EDGE"},
+}
+
+// filterColumns returns the subset (and order) of allColumns named in selected, or
+// allColumns unchanged if selected is empty. It returns a usage error if selected names
+// a column that isn't in allColumns.
+func filterColumns(allColumns []column, selected []string) ([]column, error) {
+	if len(selected) == 0 {
+		return allColumns, nil
+	}
+	byName := make(map[string]column, len(allColumns))
+	for _, col := range allColumns {
+		byName[col.Name] = col
+	}
+	filtered := make([]column, 0, len(selected))
+	for _, name := range selected {
+		col, ok := byName[name]
+		if !ok {
+			return nil, cliutil.UsageError("'%s' is not a valid column, valid columns are %s", name, columnNames(allColumns))
+		}
+		filtered = append(filtered, col)
+	}
+	return filtered, nil
+}
+
+func columnNames(columns []column) string {
+	names := make([]string, len(columns))
+	for i, col := range columns {
+		names[i] = col.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+func parseColumnsFlag(c *cli.Context) []string {
+	raw := c.String(columnsFlag.Name)
+	if raw == "" {
+		return nil
+	}
+	var columns []string
+	for _, name := range strings.Split(raw, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			columns = append(columns, name)
+		}
+	}
+	return columns
+}
+
+func tunnelColumnValue(t *tunnelstore.Tunnel, name string, showRecentlyDisconnected bool) string {
+	switch name {
+	case "id":
+		return t.ID.String()
+	case "name":
+		return t.Name
+	case "createdAt":
+		return t.CreatedAt.Format(time.RFC3339)
+	case "connections":
+		return fmtConnections(t.Connections, showRecentlyDisconnected)
+	default:
+		return ""
+	}
+}
+
+func tunnelRows(tunnels []*tunnelstore.Tunnel, columns []column, showRecentlyDisconnected bool) (headers []string, rows [][]string) {
+	for _, col := range columns {
+		headers = append(headers, col.Header)
+	}
+	for _, t := range tunnels {
+		row := make([]string, len(columns))
+		for i, col := range columns {
+			row[i] = tunnelColumnValue(t, col.Name, showRecentlyDisconnected)
+		}
+		rows = append(rows, row)
+	}
+	return headers, rows
+}
+
+func connectorColumnValue(c *Connector, name string, showRecentlyDisconnected bool) string {
+	switch name {
+	case "id":
+		return c.ID.String()
+	case "createdAt":
+		return c.RunAt.Format(time.RFC3339)
+	case "architecture":
+		return c.Arch
+	case "version":
+		return c.Version
+	case "originIP":
+		if len(c.Connections) > 0 {
+			return c.Connections[0].OriginIP.String()
+		}
+		return ""
+	case "edge":
+		return fmtConnections(c.Connections, showRecentlyDisconnected)
+	default:
+		return ""
+	}
+}
+
+func connectorRows(connectors []*Connector, columns []column, showRecentlyDisconnected bool) (headers []string, rows [][]string) {
+	for _, col := range columns {
+		headers = append(headers, col.Header)
+	}
+	for _, c := range connectors {
+		row := make([]string, len(columns))
+		for i, col := range columns {
+			row[i] = connectorColumnValue(c, col.Name, showRecentlyDisconnected)
+		}
+		rows = append(rows, row)
+	}
+	return headers, rows
+}
+
+// renderRows prints a set of headers and rows in the given 'csv' or 'table' format.
+func renderRows(format string, headers []string, rows [][]string) error {
+	switch format {
+	case "table":
+		writer := tabWriter()
+		defer writer.Flush()
+		_, _ = fmt.Fprintln(writer, strings.Join(headers, "\t")+"\t")
+		for _, row := range rows {
+			_, _ = fmt.Fprintln(writer, strings.Join(row, "\t")+"\t")
+		}
+		return nil
+	case "csv":
+		writer := csv.NewWriter(os.Stdout)
+		defer writer.Flush()
+		if err := writer.Write(headers); err != nil {
+			return err
+		}
+		return writer.WriteAll(rows)
+	default:
+		return errors.Errorf("Unknown output format '%s'", format)
+	}
+}
+
 func buildRunCommand() *cli.Command {
 	flags := []cli.Flag{
 		forceFlag,
 		credentialsFileFlag,
+		credentialsStoreFlag,
 		selectProtocolFlag,
 		featuresFlag,
 	}
@@ -608,15 +1152,40 @@ func runCommand(c *cli.Context) error {
 			"your origin will not be reachable. You should remove the `hostname` property to avoid this warning.")
 	}
 
-	return runNamedTunnel(sc, tunnelRef)
+	return runNamedTunnel(sc, tunnelRef, c.String(CredFileFlag), c.String(CredentialsStoreFlag))
 }
 
-func runNamedTunnel(sc *subcommandContext, tunnelRef string) error {
+func runNamedTunnel(sc *subcommandContext, tunnelRef string, credFile string, credentialsStore string) error {
 	tunnelID, err := sc.findID(tunnelRef)
 	if err != nil {
 		return errors.Wrap(err, "error parsing tunnel ID")
 	}
 
+	if credFile != "" {
+		if err := migrateCredentialsToStore(tunnelID, credFile, credentialsStore); err != nil {
+			sc.log.Warn().Err(err).Msg("failed to migrate tunnel credentials to the configured credentials store")
+		}
+
+		if _, statErr := os.Stat(credFile); os.IsNotExist(statErr) {
+			// The credentials live in the configured store rather than on disk (e.g. they
+			// were migrated above, or never written to disk to begin with). Materialize them
+			// to credFile so the tunnel engine can read them the way it always has, then wipe
+			// the plaintext copy once the tunnel stops.
+			credentials, err := readTunnelCredentials(tunnelID, credFile, credentialsStore)
+			if err != nil {
+				return errors.Wrapf(err, "unable to read tunnel credentials for %s from %s", tunnelID, credentialsStore)
+			}
+			body, err := marshalCredentials(credentials)
+			if err != nil {
+				return errors.Wrap(err, "unable to marshal tunnel credentials")
+			}
+			if err := ioutil.WriteFile(credFile, body, 0400); err != nil {
+				return errors.Wrap(err, "unable to write tunnel credentials file")
+			}
+			defer os.Remove(credFile)
+		}
+	}
+
 	sc.log.Info().Str(LogFieldTunnelID, tunnelID.String()).Msg("Starting tunnel")
 
 	return sc.run(tunnelID)
@@ -629,35 +1198,41 @@ func buildCleanupCommand() *cli.Command {
 		Usage:              "Cleanup tunnel connections",
 		UsageText:          "cloudflared tunnel [tunnel command options] cleanup [subcommand options] TUNNEL",
 		Description:        "Delete connections for tunnels with the given UUIDs or names.",
-		Flags:              []cli.Flag{cleanupClientFlag},
+		Flags:              append([]cli.Flag{cleanupClientFlag}, selectorFlags()...),
 		CustomHelpTemplate: commandHelpTemplate(),
 	}
 }
 
 func cleanupCommand(c *cli.Context) error {
-	if c.NArg() < 1 {
-		return cliutil.UsageError(`"cloudflared tunnel cleanup" requires at least 1 argument, the IDs of the tunnels to cleanup connections.`)
-	}
-
 	sc, err := newSubcommandContext(c)
 	if err != nil {
 		return err
 	}
 
-	tunnelIDs, err := sc.findIDs(c.Args().Slice())
+	tunnelIDs, fromFilter, err := tunnelIDsFromArgsOrFilter(sc, c)
 	if err != nil {
 		return err
 	}
+	if len(tunnelIDs) == 0 {
+		return cliutil.UsageError(`"cloudflared tunnel cleanup" requires at least 1 argument (the IDs of the tunnels to cleanup connections) or a selector flag such as --name-regex, --older-than, or --no-connections.`)
+	}
 
-	return sc.cleanupConnections(tunnelIDs)
+	if !fromFilter || len(tunnelIDs) == 1 {
+		return sc.cleanupConnections(tunnelIDs)
+	}
+
+	results := runBatch(tunnelIDs, batchOpConcurrency, func(tunnelID uuid.UUID) error {
+		return sc.cleanupConnections([]uuid.UUID{tunnelID})
+	})
+	return printBatchResults("cleanup", results)
 }
 
 func buildRouteCommand() *cli.Command {
 	return &cli.Command{
 		Name:      "route",
 		Action:    cliutil.ConfiguredAction(routeCommand),
-		Usage:     "Define which traffic routed from Cloudflare edge to this tunnel: requests to a DNS hostname, to a Cloudflare Load Balancer, or traffic originating from Cloudflare WARP clients",
-		UsageText: "cloudflared tunnel [tunnel command options] route [subcommand options] [dns TUNNEL HOSTNAME]|[lb TUNNEL HOSTNAME LB-POOL]|[ip NETWORK TUNNEL]",
+		Usage:     "Define which traffic routed from Cloudflare edge to this tunnel: requests to a DNS hostname, to a Cloudflare Load Balancer, a private TCP/SSH endpoint, or traffic originating from Cloudflare WARP clients",
+		UsageText: "cloudflared tunnel [tunnel command options] route [subcommand options] [dns TUNNEL HOSTNAME]|[lb TUNNEL HOSTNAME LB-POOL]|[tcp TUNNEL HOSTNAME PORT]|[ssh TUNNEL HOSTNAME]|[srv TUNNEL SERVICE PROTO HOSTNAME PORT]|[ip NETWORK TUNNEL]",
 		Description: `The route command defines how Cloudflare will proxy requests to this tunnel.
 
 To route a hostname by creating a DNS CNAME record to a tunnel:
@@ -668,6 +1243,15 @@ To use this tunnel as a load balancer origin, creating pool and load balancer if
    cloudflared tunnel route lb <tunnel ID or name> <hostname> <load balancer pool>
 You can read more at: https://developers.cloudflare.com/cloudflare-one/connections/connect-apps/routing-to-tunnel/lb
 
+To bind an arbitrary hostname+port pair to this tunnel for private-network TCP access:
+   cloudflared tunnel route tcp <tunnel ID or name> <hostname> <port>
+
+As a short-hand for routing TCP:22 with a friendly hostname:
+   cloudflared tunnel route ssh <tunnel ID or name> <hostname>
+
+To create a CNAME along with the underlying SRV record so clients doing SRV lookups can discover this tunnel:
+   cloudflared tunnel route srv <tunnel ID or name> <service> <proto> <hostname> <port>
+
 For Cloudflare WARP traffic to be routed to your private network, reachable from this tunnel as origins, use:
    cloudflared tunnel route ip <network CIDR> <tunnel ID or name>
 Further information about managing Cloudflare WARP traffic to your tunnel is available at:
@@ -677,7 +1261,7 @@ Further information about managing Cloudflare WARP traffic to your tunnel is ava
 		Subcommands: []*cli.Command{
 			buildRouteIPSubcommand(),
 		},
-		Flags: []cli.Flag{overwriteDNSFlag},
+		Flags: []cli.Flag{overwriteDNSFlag, hostnameIDNAModeFlag},
 	}
 }
 
@@ -692,7 +1276,7 @@ func dnsRouteFromArg(c *cli.Context, overwriteExisting bool) (tunnelstore.Route,
 	userHostname := c.Args().Get(userHostnameIndex)
 	if userHostname == "" {
 		return nil, cliutil.UsageError("The third argument should be the hostname")
-	} else if !validateHostname(userHostname, true) {
+	} else if !validateHostname(userHostname, true, c.String(hostnameIDNAModeFlagName)) {
 		return nil, errors.Errorf("%s is not a valid hostname", userHostname)
 	}
 	return tunnelstore.NewDNSRoute(userHostname, overwriteExisting), nil
@@ -710,7 +1294,7 @@ func lbRouteFromArg(c *cli.Context) (tunnelstore.Route, error) {
 	lbName := c.Args().Get(lbNameIndex)
 	if lbName == "" {
 		return nil, cliutil.UsageError("The third argument should be the load balancer name")
-	} else if !validateHostname(lbName, true) {
+	} else if !validateHostname(lbName, true, c.String(hostnameIDNAModeFlagName)) {
 		return nil, errors.Errorf("%s is not a valid load balancer name", lbName)
 	}
 
@@ -724,6 +1308,101 @@ func lbRouteFromArg(c *cli.Context) (tunnelstore.Route, error) {
 	return tunnelstore.NewLBRoute(lbName, lbPool), nil
 }
 
+// tcpRouteFromArg, sshRouteFromArg, and srvRouteFromArg rely on tunnelstore.NewTCPRoute
+// and tunnelstore.NewSRVRoute, new tunnelstore.Route constructors that issue the TCP/SRV
+// route API calls; they belong alongside tunnelstore.NewDNSRoute/NewLBRoute in the
+// tunnelstore package.
+func tcpRouteFromArg(c *cli.Context) (tunnelstore.Route, error) {
+	const (
+		hostnameIndex = 2
+		portIndex     = 3
+		expectedNArgs = 4
+	)
+	if c.NArg() != expectedNArgs {
+		return nil, cliutil.UsageError("Expected %d arguments, got %d", expectedNArgs, c.NArg())
+	}
+	hostname := c.Args().Get(hostnameIndex)
+	if hostname == "" {
+		return nil, cliutil.UsageError("The third argument should be the hostname")
+	} else if !validateHostname(hostname, true, c.String(hostnameIDNAModeFlagName)) {
+		return nil, errors.Errorf("%s is not a valid hostname", hostname)
+	}
+
+	port, err := parsePort(c.Args().Get(portIndex), "fourth")
+	if err != nil {
+		return nil, err
+	}
+
+	return tunnelstore.NewTCPRoute(hostname, port), nil
+}
+
+func sshRouteFromArg(c *cli.Context) (tunnelstore.Route, error) {
+	const (
+		hostnameIndex = 2
+		expectedNArgs = 3
+		sshPort       = 22
+	)
+	if c.NArg() != expectedNArgs {
+		return nil, cliutil.UsageError("Expected %d arguments, got %d", expectedNArgs, c.NArg())
+	}
+	hostname := c.Args().Get(hostnameIndex)
+	if hostname == "" {
+		return nil, cliutil.UsageError("The third argument should be the hostname")
+	} else if !validateHostname(hostname, true, c.String(hostnameIDNAModeFlagName)) {
+		return nil, errors.Errorf("%s is not a valid hostname", hostname)
+	}
+
+	return tunnelstore.NewTCPRoute(hostname, sshPort), nil
+}
+
+func srvRouteFromArg(c *cli.Context) (tunnelstore.Route, error) {
+	const (
+		serviceIndex  = 2
+		protoIndex    = 3
+		hostnameIndex = 4
+		portIndex     = 5
+		expectedNArgs = 6
+	)
+	if c.NArg() != expectedNArgs {
+		return nil, cliutil.UsageError("Expected %d arguments, got %d", expectedNArgs, c.NArg())
+	}
+
+	service := c.Args().Get(serviceIndex)
+	if service == "" {
+		return nil, cliutil.UsageError("The third argument should be the SRV service name, e.g. sip")
+	}
+
+	proto := c.Args().Get(protoIndex)
+	if proto != "tcp" && proto != "udp" {
+		return nil, cliutil.UsageError("The fourth argument should be the SRV protocol, 'tcp' or 'udp'")
+	}
+
+	hostname := c.Args().Get(hostnameIndex)
+	if hostname == "" {
+		return nil, cliutil.UsageError("The fifth argument should be the hostname")
+	} else if !validateHostname(hostname, true, c.String(hostnameIDNAModeFlagName)) {
+		return nil, errors.Errorf("%s is not a valid hostname", hostname)
+	}
+
+	port, err := parsePort(c.Args().Get(portIndex), "sixth")
+	if err != nil {
+		return nil, err
+	}
+
+	return tunnelstore.NewSRVRoute(service, proto, hostname, port), nil
+}
+
+func parsePort(s string, argOrdinal string) (uint16, error) {
+	if s == "" {
+		return 0, cliutil.UsageError("The %s argument should be the target port", argOrdinal)
+	}
+	port, err := strconv.ParseUint(s, 10, 16)
+	if err != nil {
+		return 0, errors.Errorf("%s is not a valid port", s)
+	}
+	return uint16(port), nil
+}
+
 var nameRegex = regexp.MustCompile("^[_a-zA-Z0-9][-_.a-zA-Z0-9]*$")
 var hostNameRegex = regexp.MustCompile("^[*_a-zA-Z0-9][-_.a-zA-Z0-9]*$")
 
@@ -734,13 +1413,35 @@ func validateName(s string, allowWildcardSubdomain bool) bool {
 	return nameRegex.MatchString(s)
 }
 
-func validateHostname(s string, allowWildcardSubdomain bool) bool {
-	// Slightly stricter than PunyCodeProfile
-	idnaProfile := idna.New(
-		idna.ValidateLabels(true),
-		idna.VerifyDNSLength(true))
+// idnaProfileForMode returns the IDNA/UTS-46 profile used to convert a hostname to
+// punycode before it's validated as a DNS label. "strict" is the original, most
+// restrictive behavior; "compatible" and "registration" relax it to accept legitimate
+// internationalized hostnames that "strict" rejects.
+func idnaProfileForMode(mode string) *idna.Profile {
+	switch mode {
+	case idnaModeCompatible:
+		return idna.New(
+			idna.Transitional(true),
+			idna.MapForLookup(),
+			idna.BidiRule(),
+			idna.ValidateLabels(true),
+			idna.VerifyDNSLength(true))
+	case idnaModeRegistration:
+		// idna.ValidateForRegistration() already combines ValidateLabels, VerifyDNSLength,
+		// StrictDomainName and BidiRule with the RFC 5891 §4 registration mapping; hand-
+		// assembling the same options from idna.New leaves the mapping nil and silently
+		// falls back to the "strict" behavior.
+		return idna.New(idna.ValidateForRegistration())
+	default:
+		// Slightly stricter than PunyCodeProfile
+		return idna.New(
+			idna.ValidateLabels(true),
+			idna.VerifyDNSLength(true))
+	}
+}
 
-	puny, err := idnaProfile.ToASCII(s)
+func validateHostname(s string, allowWildcardSubdomain bool, idnaMode string) bool {
+	puny, err := idnaProfileForMode(idnaMode).ToASCII(s)
 	return err == nil && validateName(puny, allowWildcardSubdomain)
 }
 
@@ -777,8 +1478,35 @@ func routeCommand(c *cli.Context) error {
 		if err != nil {
 			return err
 		}
+	case "tcp":
+		tunnelID, err = sc.findID(c.Args().Get(tunnelIDIndex))
+		if err != nil {
+			return err
+		}
+		route, err = tcpRouteFromArg(c)
+		if err != nil {
+			return err
+		}
+	case "ssh":
+		tunnelID, err = sc.findID(c.Args().Get(tunnelIDIndex))
+		if err != nil {
+			return err
+		}
+		route, err = sshRouteFromArg(c)
+		if err != nil {
+			return err
+		}
+	case "srv":
+		tunnelID, err = sc.findID(c.Args().Get(tunnelIDIndex))
+		if err != nil {
+			return err
+		}
+		route, err = srvRouteFromArg(c)
+		if err != nil {
+			return err
+		}
 	default:
-		return cliutil.UsageError("%s is not a recognized route type. Supported route types are dns and lb", routeType)
+		return cliutil.UsageError("%s is not a recognized route type. Supported route types are dns, lb, tcp, ssh and srv", routeType)
 	}
 
 	res, err := sc.route(tunnelID, route)
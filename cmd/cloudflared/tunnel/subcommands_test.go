@@ -0,0 +1,102 @@
+package tunnel
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/zalando/go-keyring"
+
+	"github.com/cloudflare/cloudflared/connection"
+)
+
+func TestFilterColumns(t *testing.T) {
+	columns, err := filterColumns(tunnelColumns, nil)
+	if err != nil {
+		t.Fatalf("filterColumns with no selection returned error: %v", err)
+	}
+	if len(columns) != len(tunnelColumns) {
+		t.Errorf("filterColumns with no selection = %v, want all of %v", columns, tunnelColumns)
+	}
+
+	columns, err = filterColumns(tunnelColumns, []string{"name", "id"})
+	if err != nil {
+		t.Fatalf("filterColumns(%v) returned error: %v", []string{"name", "id"}, err)
+	}
+	if len(columns) != 2 || columns[0].Name != "name" || columns[1].Name != "id" {
+		t.Errorf("filterColumns(name,id) = %v, want [name id] in that order", columns)
+	}
+
+	if _, err := filterColumns(tunnelColumns, []string{"id", "nmae"}); err == nil {
+		t.Error("filterColumns with an unknown column name should return an error")
+	}
+}
+
+func TestMigrateCredentialsToStoreDoesNotOverwriteExisting(t *testing.T) {
+	keyring.MockInit()
+
+	tunnelID := uuid.New()
+	filePath := filepath.Join(t.TempDir(), "cred.json")
+	body, err := marshalCredentials(&connection.Credentials{TunnelID: tunnelID})
+	if err != nil {
+		t.Fatalf("failed to marshal test credentials: %v", err)
+	}
+	if err := ioutil.WriteFile(filePath, body, 0400); err != nil {
+		t.Fatalf("failed to write test credentials file: %v", err)
+	}
+
+	if err := keyring.Set(credentialsKeyringService, tunnelID.String(), "existing"); err != nil {
+		t.Fatalf("failed to seed keyring: %v", err)
+	}
+
+	if err := migrateCredentialsToStore(tunnelID, filePath, credentialsStoreKeyring); err == nil {
+		t.Error("migrateCredentialsToStore should fail when credentials already exist in the store")
+	}
+
+	stored, err := keyring.Get(credentialsKeyringService, tunnelID.String())
+	if err != nil {
+		t.Fatalf("failed to read back keyring entry: %v", err)
+	}
+	if stored != "existing" {
+		t.Errorf("migrateCredentialsToStore overwrote the existing keyring entry: got %q", stored)
+	}
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		t.Error("migrateCredentialsToStore removed the credentials file despite failing")
+	}
+}
+
+func TestValidateHostnameIDNAModes(t *testing.T) {
+	longLabel := strings.Repeat("a", 70)
+	longHostname := strings.Repeat("a.", 200) + "example.com"
+
+	tests := []struct {
+		name     string
+		hostname string
+		idnaMode string
+		want     bool
+	}{
+		{"simple ascii, strict", "example.com", idnaModeStrict, true},
+		{"simple ascii, compatible", "example.com", idnaModeCompatible, true},
+		{"simple ascii, registration", "example.com", idnaModeRegistration, true},
+		{"label too long, strict", longLabel + ".example.com", idnaModeStrict, false},
+		{"label too long, compatible", longLabel + ".example.com", idnaModeCompatible, false},
+		{"label too long, registration", longLabel + ".example.com", idnaModeRegistration, false},
+		{"hostname too long, strict", longHostname, idnaModeStrict, false},
+		{"hostname too long, compatible", longHostname, idnaModeCompatible, false},
+		{"hostname too long, registration", longHostname, idnaModeRegistration, false},
+		{"unicode, strict", "xn--caf-dma.example.com", idnaModeStrict, true},
+		{"unicode, compatible", "café.example.com", idnaModeCompatible, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := validateHostname(test.hostname, true, test.idnaMode)
+			if got != test.want {
+				t.Errorf("validateHostname(%q, true, %q) = %v, want %v", test.hostname, test.idnaMode, got, test.want)
+			}
+		})
+	}
+}